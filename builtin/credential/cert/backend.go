@@ -0,0 +1,95 @@
+package cert
+
+import (
+	"fmt"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+// backend is the shared receiver for every path in this package. It embeds
+// *framework.Backend so the package's many (b *backend) handlers have
+// somewhere to hang, the same way every other framework-based backend in
+// this tree is structured.
+//
+// The CRL metadata/serial caches also live here rather than as package
+// state: a process can have more than one cert mount, or unmount and
+// remount one, and those mounts must not share a cache or a background
+// refresher -- see Factory.
+type backend struct {
+	*framework.Backend
+
+	crlShards [numCRLShards]*crlShard
+
+	serialCache           *lru.Cache
+	serialCacheHits       uint64
+	serialCacheMisses     uint64
+	serialCacheStatsMutex sync.Mutex
+
+	// refresherStop stops this instance's background CRL refresh goroutine
+	// (see startCRLRefresher). Closed from Backend.Clean on unmount, so a
+	// mount doesn't leak its refresher goroutine for the life of the process.
+	refresherStop chan struct{}
+}
+
+// Factory returns a configured cert auth backend, as required by Vault's
+// logical.Factory signature.
+func Factory(conf *logical.BackendConfig) (logical.Backend, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("configuration passed into backend is nil")
+	}
+
+	b := Backend()
+	b.Setup(conf)
+
+	if err := b.populateCRLs(conf.StorageView); err != nil {
+		return nil, err
+	}
+	b.refresherStop = b.startCRLRefresher(conf.StorageView)
+	b.Backend.Clean = func() {
+		close(b.refresherStop)
+	}
+
+	return b, nil
+}
+
+// Backend assembles the framework.Backend for this package: every path
+// defined here (crls/<name>, crl/refresh/<name>, crls/stats, ocsp/<name>,
+// revoke, config/local_crl) is registered so it's actually reachable
+// through the router, not just defined.
+//
+// This package doesn't include the trusted-certificate store
+// (certs/<name>) or the "login" path that matches a presented chain
+// against it -- those live in path_certs.go/path_login.go, which aren't
+// part of this tree. A real login handler would call findSerialInCRLs and
+// checkOCSP (see their doc comments) against the chain it authenticates
+// before issuing an Auth response; until that file exists here, those
+// checks have no HTTP-reachable call site.
+func Backend() *backend {
+	var b backend
+	b.crlShards = newCRLShards()
+	b.serialCache = mustNewSerialCache()
+	b.Backend = &framework.Backend{
+		Help: backendHelp,
+		Paths: []*framework.Path{
+			// crls/stats comes before crls/<name>, whose name pattern would
+			// otherwise also match "stats".
+			pathCRLStats(&b),
+			pathCRLs(&b),
+			pathCRLRefresh(&b),
+			pathOCSP(&b),
+			pathRevoke(&b),
+			pathLocalCRLConfig(&b),
+		},
+	}
+	return &b
+}
+
+const backendHelp = `
+The cert backend allows authentication using TLS client certificates. The
+paths registered here manage the revocation data (CRLs, OCSP responders,
+and the backend-issued "revoke" endpoint) that a login check consults
+against the client's presented chain.
+`
@@ -0,0 +1,41 @@
+package cert
+
+import "testing"
+
+// TestBackend_RegistersRevocationPaths verifies that every path defined in
+// this package (CRLs, CRL refresh/stats, OCSP config, revoke, and local CRL
+// signing config) is actually registered on the assembled backend, not just
+// defined and never wired up.
+func TestBackend_RegistersRevocationPaths(t *testing.T) {
+	b := Backend()
+
+	wantPrefixes := []string{
+		"crls/",
+		"crl/refresh/",
+		"crls/stats",
+		"ocsp/",
+		"revoke",
+		"config/local_crl",
+	}
+
+	for _, want := range wantPrefixes {
+		found := false
+		for _, path := range b.Backend.Paths {
+			if path.Pattern == want || len(path.Pattern) >= len(want) && path.Pattern[:len(want)] == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("expected a registered path matching %q, got patterns %#v", want, patternsOf(b))
+		}
+	}
+}
+
+func patternsOf(b *backend) []string {
+	patterns := make([]string, len(b.Backend.Paths))
+	for i, path := range b.Backend.Paths {
+		patterns[i] = path.Pattern
+	}
+	return patterns
+}
@@ -1,18 +1,41 @@
 package cert
 
 import (
+	"crypto/sha1"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/structs"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/hashicorp/vault/helper/certutil"
 	"github.com/hashicorp/vault/logical"
 	"github.com/hashicorp/vault/logical/framework"
 )
 
+// CertificateHold (RFC 5280 section 5.3.1 reason code 6) marks a revocation as
+// provisional: the serial may be un-revoked if it stops appearing on a
+// later CRL, unlike the other reason codes. See revocationStatus.
+const reasonCodeCertificateHold = 6
+
+var reasonCodeExtensionOID = asn1.ObjectIdentifier{2, 5, 29, 21}
+
+// Bounds on the refresh interval computed from a CRL's NextUpdate field.
+// These keep a CRL with a bogus or missing NextUpdate (or one far in the
+// future) from starving or flooding the distribution point.
+const (
+	minCRLRefreshInterval = 5 * time.Minute
+	maxCRLRefreshInterval = 12 * time.Hour
+)
+
 func pathCRLs(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: "crls/" + framework.GenericNameRegex("name"),
@@ -30,15 +53,37 @@ is ignored; if the CRL is no longer valid, delete it
 using the same name as specified here.`,
 			},
 
+			"url": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `If set, the CRL distribution point URL to fetch
+the CRL from instead of (or in addition to, on the first write) the
+inline "crl" parameter. When set, the backend periodically re-fetches
+the CRL and refreshes the in-memory copy on an interval derived from
+the CRL's NextUpdate field, bounded to between five minutes and twelve
+hours.`,
+			},
+
 			"serial": &framework.FieldSchema{
 				Type: framework.TypeString,
 				Description: `If specified, for a read, information for this
-serial will be returned rather than the named CRL.
-This can be a hex-formatted string separated
+serial will be returned rather than the named CRL, along with a
+"statuses" map giving each matching CRL's revocation status -- "revoked",
+or "hold" for a CertificateHold (reason code 6) entry, which is lifted
+automatically the next time that CRL is re-fetched or rewritten without
+the serial. This can be a hex-formatted string separated
 by : or -, or an integer string; this will be
 assumed to be base 10 unless prefixed by "0x"
 for base 16 or "0" for base 8.`,
 			},
+
+			"issuer_name_hash": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `Used together with "serial" on a read: a hash of
+the issuer name of the cert being checked, computed the same way as the
+"issuer_name_hash" returned on a CRL. When set, only CRLs whose
+issuer_name_hash matches are considered, preventing a serial collision
+between two different CAs from appearing as a revocation.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -52,15 +97,170 @@ for base 16 or "0" for base 8.`,
 	}
 }
 
-var (
-	crls           = map[string]CRLInfo{}
-	crlUpdateMutex = &sync.RWMutex{}
-)
+func pathCRLRefresh(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "crl/refresh/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "The name of the certificate",
+			},
+		},
 
-func populateCRLs(storage logical.Storage) error {
-	crlUpdateMutex.Lock()
-	defer crlUpdateMutex.Unlock()
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.WriteOperation: b.pathCRLRefreshWrite,
+		},
+
+		HelpSynopsis:    pathCRLRefreshHelpSyn,
+		HelpDescription: pathCRLRefreshHelpDesc,
+	}
+}
+
+func pathCRLStats(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "crls/stats",
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathCRLStatsRead,
+		},
 
+		HelpSynopsis:    pathCRLStatsHelpSyn,
+		HelpDescription: pathCRLStatsHelpDesc,
+	}
+}
+
+// numCRLShards controls how many independent metadata maps (and mutexes)
+// the CRL cache is split across, keyed by the first byte of the CRL name,
+// to keep a hot login path from serializing behind a single global lock.
+const numCRLShards = 256
+
+// serialCacheSize bounds the LRU used for (crlName, serial) lookups. Each
+// entry is small (a bool plus a RevokedSerialInfo), so this trades a modest
+// amount of RAM for not needing to hold every CRL's full serial set in
+// memory at once.
+const serialCacheSize = 500000
+
+// crlMeta is the small, always-resident summary of a loaded CRL. The full
+// Serials map lives only in storage and in the serialCache LRU, populated
+// lazily.
+type crlMeta struct {
+	IssuerNameHash string
+	SerialCount    int
+	URL            string
+	LastFetch      time.Time
+	NextRefresh    time.Time
+	FetchError     string
+}
+
+// crlShard holds one shard's metadata map plus the per-CRL-name locks used
+// to serialize a given CRL's load-modify-store sequence (see crlWriteLock).
+// Both are scoped to a single backend instance: they live on (b *backend),
+// not as package state, so two mounts (or a mount that's been unmounted and
+// remounted) never share a cache or a lock.
+type crlShard struct {
+	lock sync.RWMutex
+	meta map[string]crlMeta
+
+	writeMu    sync.Mutex
+	writeLocks map[string]*sync.Mutex
+}
+
+func newCRLShards() [numCRLShards]*crlShard {
+	var shards [numCRLShards]*crlShard
+	for i := range shards {
+		shards[i] = &crlShard{meta: map[string]crlMeta{}, writeLocks: map[string]*sync.Mutex{}}
+	}
+	return shards
+}
+
+func (b *backend) shardForCRL(name string) *crlShard {
+	if name == "" {
+		return b.crlShards[0]
+	}
+	return b.crlShards[name[0]]
+}
+
+// crlWriteLock returns the lock serializing load-modify-store sequences
+// against the named CRL (see storeCRL and appendLocalRevocation), creating
+// it on first use. storeCRL's read-modify-write deletes every existing
+// per-serial entry before rewriting the ones it knows about, so without this
+// lock two concurrent writers against the same name (e.g. two "revoke"
+// calls against _local, or a write racing a refresh) can silently drop each
+// other's serials.
+func (b *backend) crlWriteLock(name string) *sync.Mutex {
+	shard := b.shardForCRL(name)
+
+	shard.writeMu.Lock()
+	defer shard.writeMu.Unlock()
+
+	l, ok := shard.writeLocks[name]
+	if !ok {
+		l = &sync.Mutex{}
+		shard.writeLocks[name] = l
+	}
+	return l
+}
+
+// serialCacheEntry caches the outcome of looking a serial up against one
+// named CRL's storage entry, so a repeated miss doesn't re-read storage.
+type serialCacheEntry struct {
+	found bool
+	info  RevokedSerialInfo
+}
+
+type serialCacheKey struct {
+	name   string
+	serial string
+}
+
+func mustNewSerialCache() *lru.Cache {
+	c, err := lru.New(serialCacheSize)
+	if err != nil {
+		// Only returns an error for a non-positive size, which is a
+		// programmer error, not a runtime condition.
+		panic(err)
+	}
+	return c
+}
+
+func (b *backend) getCRLMeta(name string) (crlMeta, bool) {
+	shard := b.shardForCRL(name)
+	shard.lock.RLock()
+	defer shard.lock.RUnlock()
+	meta, ok := shard.meta[name]
+	return meta, ok
+}
+
+func (b *backend) setCRLMeta(name string, meta crlMeta) {
+	shard := b.shardForCRL(name)
+	shard.lock.Lock()
+	shard.meta[name] = meta
+	shard.lock.Unlock()
+}
+
+func (b *backend) deleteCRLMeta(name string) {
+	shard := b.shardForCRL(name)
+	shard.lock.Lock()
+	delete(shard.meta, name)
+	shard.lock.Unlock()
+}
+
+func (b *backend) crlNames() []string {
+	names := []string{}
+	for _, shard := range b.crlShards {
+		shard.lock.RLock()
+		for name := range shard.meta {
+			names = append(names, name)
+		}
+		shard.lock.RUnlock()
+	}
+	return names
+}
+
+// populateCRLs loads only the metadata for every persisted CRL at startup;
+// the full serial sets are left in storage and pulled into serialCache
+// lazily by findSerialInCRLs.
+func (b *backend) populateCRLs(storage logical.Storage) error {
 	keys, err := storage.List("crls/")
 	if err != nil {
 		return fmt.Errorf("error listing CRLs: %v", err)
@@ -70,6 +270,11 @@ func populateCRLs(storage logical.Storage) error {
 	}
 
 	for _, key := range keys {
+		// Storage.List returns a trailing-slash entry for each CRL's
+		// serials/ sub-tree alongside the CRL's own metadata entry; skip it.
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
 		entry, err := storage.Get("crls/" + key)
 		if err != nil {
 			return fmt.Errorf("error loading CRL %s: %v", key, err)
@@ -78,31 +283,215 @@ func populateCRLs(storage logical.Storage) error {
 			continue
 		}
 		var crlInfo CRLInfo
-		err = entry.DecodeJSON(&crlInfo)
-		if err != nil {
+		if err := entry.DecodeJSON(&crlInfo); err != nil {
 			return fmt.Errorf("error decoding CRL %s: %v", key, err)
 		}
-		crls[key] = crlInfo
+
+		count, err := countCRLSerials(storage, key)
+		if err != nil {
+			return fmt.Errorf("error counting serials for CRL %s: %v", key, err)
+		}
+
+		meta := metaFromCRLInfo(crlInfo)
+		meta.SerialCount = count
+		b.setCRLMeta(key, meta)
 	}
 
 	return nil
 }
 
-func findSerialInCRLs(serial *big.Int) map[string]RevokedSerialInfo {
-	crlUpdateMutex.RLock()
-	defer crlUpdateMutex.RUnlock()
+// countCRLSerials returns the number of per-serial storage entries recorded
+// for a CRL, without reading any of them.
+func countCRLSerials(storage logical.Storage, name string) (int, error) {
+	keys, err := storage.List(crlSerialsPrefix(name))
+	if err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+func metaFromCRLInfo(crlInfo CRLInfo) crlMeta {
+	return crlMeta{
+		IssuerNameHash: crlInfo.IssuerNameHash,
+		SerialCount:    len(crlInfo.Serials),
+		URL:            crlInfo.URL,
+		LastFetch:      crlInfo.LastFetch,
+		NextRefresh:    crlInfo.NextRefresh,
+		FetchError:     crlInfo.FetchError,
+	}
+}
+
+// findSerialInCRLs returns every loaded CRL that lists serial as revoked.
+// If issuerNameHash is non-empty, a CRL is only considered a match when its
+// own IssuerNameHash agrees -- this keeps two CAs that happen to reuse a
+// serial number from shadowing each other's revocations. An empty
+// issuerNameHash disables the filter, matching the historical serial-only
+// behavior.
+//
+// Unlike the original implementation, this never holds a full CRL's serial
+// map in memory: each named CRL is consulted independently through
+// serialCache, which is populated lazily from storage and evicts the least
+// recently used entries once it holds serialCacheSize of them.
+func (b *backend) findSerialInCRLs(storage logical.Storage, serial *big.Int, issuerNameHash string) map[string]RevokedSerialInfo {
 	ret := map[string]RevokedSerialInfo{}
-	for key, crl := range crls {
-		if crl.Serials == nil {
+	serialHex := serial.String()
+
+	for _, name := range b.crlNames() {
+		meta, ok := b.getCRLMeta(name)
+		if !ok {
 			continue
 		}
-		if info, ok := crl.Serials[serial.String()]; ok {
-			ret[key] = info
+
+		info, found := b.lookupSerialInCRL(storage, name, serialHex)
+		if !found {
+			continue
+		}
+
+		// Prefer the CRL-level issuer hash; fall back to the per-serial hash
+		// for a multi-issuer CRL (like _local) that has no single issuer of
+		// its own.
+		entryIssuerHash := meta.IssuerNameHash
+		if entryIssuerHash == "" {
+			entryIssuerHash = info.IssuerNameHash
+		}
+		if issuerNameHash != "" && entryIssuerHash != "" && entryIssuerHash != issuerNameHash {
+			continue
 		}
+
+		ret[name] = info
 	}
+
 	return ret
 }
 
+// crlSerialsPrefix is the storage folder holding one entry per revoked
+// serial for a CRL, independent of that CRL's own metadata entry at
+// "crls/<name>".
+func crlSerialsPrefix(name string) string {
+	return "crls/" + name + "/serials/"
+}
+
+func crlSerialStorageKey(name, serialHex string) string {
+	return crlSerialsPrefix(name) + serialHex
+}
+
+// lookupSerialInCRL answers whether serialHex is revoked by the named CRL,
+// consulting serialCache before falling back to a storage read. Each
+// serial is stored as its own entry under crlSerialsPrefix(name), so a
+// cache miss only ever reads the one serial being looked up -- not the
+// CRL's full revoked set.
+func (b *backend) lookupSerialInCRL(storage logical.Storage, name, serialHex string) (RevokedSerialInfo, bool) {
+	key := serialCacheKey{name: name, serial: serialHex}
+
+	if cached, ok := b.serialCache.Get(key); ok {
+		b.recordSerialCacheLookup(true)
+		entry := cached.(serialCacheEntry)
+		return entry.info, entry.found
+	}
+	b.recordSerialCacheLookup(false)
+
+	entry, err := storage.Get(crlSerialStorageKey(name, serialHex))
+	if err != nil || entry == nil {
+		b.serialCache.Add(key, serialCacheEntry{found: false})
+		return RevokedSerialInfo{}, false
+	}
+
+	var info RevokedSerialInfo
+	if err := entry.DecodeJSON(&info); err != nil {
+		return RevokedSerialInfo{}, false
+	}
+
+	b.serialCache.Add(key, serialCacheEntry{found: true, info: info})
+
+	return info, true
+}
+
+func (b *backend) recordSerialCacheLookup(hit bool) {
+	b.serialCacheStatsMutex.Lock()
+	if hit {
+		b.serialCacheHits++
+	} else {
+		b.serialCacheMisses++
+	}
+	b.serialCacheStatsMutex.Unlock()
+}
+
+// invalidateSerialCache drops every cached lookup for name. It is coarse
+// (the LRU doesn't index by name prefix) but cheap relative to a CRL
+// write/refresh, and guarantees a stale "not revoked" entry can't survive
+// past the CRL that produced it.
+func (b *backend) invalidateSerialCache(name string) {
+	for _, key := range b.serialCache.Keys() {
+		if k, ok := key.(serialCacheKey); ok && k.name == name {
+			b.serialCache.Remove(key)
+		}
+	}
+}
+
+// hashIssuerName computes an 8-hex-char fingerprint of an issuer name: the
+// DER encoding of the name with its string attribute values canonicalized
+// (lowercased, whitespace collapsed), SHA-1 hashed, with the first four
+// bytes read as a little-endian integer. This is modeled on OpenSSL's
+// X509_NAME_hash, but is not guaranteed to be bit-for-bit compatible with
+// it -- Go's asn1.Marshal encodes each canonicalized value as a
+// PrintableString or UTF8String depending on its characters, rather than
+// OpenSSL's canonical re-encoding, so the output of `openssl x509 -issuer_hash` may differ from
+// this value for the same name. It is only guaranteed to be stable and
+// collision-resistant for matching revocations within this backend.
+func hashIssuerName(name pkix.Name) (string, error) {
+	der, err := asn1.Marshal(canonicalRDNSequence(name.ToRDNSequence()))
+	if err != nil {
+		return "", fmt.Errorf("error canonicalizing issuer name: %v", err)
+	}
+
+	sum := sha1.Sum(der)
+	return fmt.Sprintf("%08x", binary.LittleEndian.Uint32(sum[:4])), nil
+}
+
+func canonicalRDNSequence(in pkix.RDNSequence) pkix.RDNSequence {
+	out := make(pkix.RDNSequence, len(in))
+	for i, rdn := range in {
+		set := make([]pkix.AttributeTypeAndValue, len(rdn))
+		for j, atv := range rdn {
+			set[j] = atv
+			if s, ok := atv.Value.(string); ok {
+				set[j].Value = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+			}
+		}
+		out[i] = set
+	}
+	return out
+}
+
+// reasonCodeFromExtensions extracts the CRL entry reason code (RFC 5280
+// section 5.3.1) from a revoked certificate's extensions, defaulting to 0
+// (unspecified) if the extension is absent or malformed.
+func reasonCodeFromExtensions(extensions []pkix.Extension) int {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(reasonCodeExtensionOID) {
+			continue
+		}
+		var reason asn1.Enumerated
+		if _, err := asn1.Unmarshal(ext.Value, &reason); err == nil {
+			return int(reason)
+		}
+	}
+	return 0
+}
+
+// revocationStatus classifies a revocation entry for display and policy
+// purposes. CertificateHold (reason 6) is provisional: nothing in this
+// package re-checks a hold on its own, but because storeCRL always replaces
+// a named CRL's entire Serials map (rather than merging into it), a held
+// serial is automatically lifted the next time that CRL is re-fetched or
+// rewritten without it.
+func revocationStatus(info RevokedSerialInfo) string {
+	if info.ReasonCode == reasonCodeCertificateHold {
+		return "hold"
+	}
+	return "revoked"
+}
+
 func parseSerialString(input string) (*big.Int, error) {
 	ret := &big.Int{}
 
@@ -137,16 +526,22 @@ func (b *backend) pathCRLDelete(
 		return logical.ErrorResponse(`"name" parameter cannot be empty`), nil
 	}
 
-	crlUpdateMutex.Lock()
-	defer crlUpdateMutex.Unlock()
-
-	_, ok := crls[name]
-	if !ok {
+	if _, ok := b.getCRLMeta(name); !ok {
 		return logical.ErrorResponse(fmt.Sprintf(
 			"no such CRL %s", name,
 		)), nil
 	}
 
+	lock := b.crlWriteLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := deleteCRLSerials(req.Storage, name); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf(
+			"error deleting crl %s: %v", name, err),
+		), nil
+	}
+
 	err := req.Storage.Delete("crls/" + name)
 	if err != nil {
 		return logical.ErrorResponse(fmt.Sprintf(
@@ -154,7 +549,8 @@ func (b *backend) pathCRLDelete(
 		), nil
 	}
 
-	delete(crls, name)
+	b.deleteCRLMeta(name)
+	b.invalidateSerialCache(name)
 
 	return nil, nil
 }
@@ -163,13 +559,11 @@ func (b *backend) pathCRLRead(
 	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := strings.ToLower(d.Get("name").(string))
 	serialStr := d.Get("serial").(string)
+	issuerNameHash := d.Get("issuer_name_hash").(string)
 	if name == "" && serialStr == "" {
 		return logical.ErrorResponse(`"name" or "serial" parameter must be set`), nil
 	}
 
-	crlUpdateMutex.RLock()
-	defer crlUpdateMutex.RUnlock()
-
 	var retData map[string]interface{}
 
 	if serialStr != "" {
@@ -178,17 +572,48 @@ func (b *backend) pathCRLRead(
 			return logical.ErrorResponse(err.Error()), nil
 		}
 
-		ret := findSerialInCRLs(serial)
-		retData = structs.New(&ret).Map()
+		ret := b.findSerialInCRLs(req.Storage, serial, issuerNameHash)
+
+		// ret is a map, and fatih/structs panics on anything that isn't
+		// (a pointer to) a struct, so it can only be used per-entry here,
+		// not on ret itself.
+		retData = make(map[string]interface{}, len(ret))
+		for crlName, info := range ret {
+			retData[crlName] = structs.New(&info).Map()
+		}
+
+		statuses := make(map[string]string, len(ret))
+		for crlName, info := range ret {
+			statuses[crlName] = revocationStatus(info)
+		}
+		retData["statuses"] = statuses
 	} else {
-		crl, ok := crls[name]
-		if !ok {
+		if _, ok := b.getCRLMeta(name); !ok {
+			return logical.ErrorResponse(fmt.Sprintf(
+				"no such CRL %s", name,
+			)), nil
+		}
+
+		crl, err := loadFullCRLInfo(req.Storage, name)
+		if err != nil {
 			return logical.ErrorResponse(fmt.Sprintf(
 				"no such CRL %s", name,
 			)), nil
 		}
 
 		retData = structs.New(&crl).Map()
+
+		if name == localCRLName {
+			// A signing failure (no key configured yet, unsupported key
+			// type, ...) shouldn't make the plain JSON serial listing
+			// unreadable; report it alongside the rest of the data instead.
+			der, err := buildLocalCRLDER(req.Storage, crl)
+			if err != nil {
+				retData["crl_der_error"] = err.Error()
+			} else {
+				retData["crl_der"] = der
+			}
+		}
 	}
 
 	return &logical.Response{
@@ -196,6 +621,41 @@ func (b *backend) pathCRLRead(
 	}, nil
 }
 
+func (b *backend) pathCRLStatsRead(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	names := b.crlNames()
+
+	totalSerials := 0
+	for _, name := range names {
+		if meta, ok := b.getCRLMeta(name); ok {
+			totalSerials += meta.SerialCount
+		}
+	}
+
+	b.serialCacheStatsMutex.Lock()
+	hits, misses := b.serialCacheHits, b.serialCacheMisses
+	b.serialCacheStatsMutex.Unlock()
+
+	var hitRatio float64
+	if total := hits + misses; total > 0 {
+		hitRatio = float64(hits) / float64(total)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"crl_count":              len(names),
+			"total_serial_count":     totalSerials,
+			"serial_cache_entries":   b.serialCache.Len(),
+			"serial_cache_hits":      hits,
+			"serial_cache_misses":    misses,
+			"serial_cache_hit_ratio": hitRatio,
+			// Rough estimate: each cached entry is a struct key plus a
+			// RevokedSerialInfo, well under 256 bytes in practice.
+			"serial_cache_estimated_bytes": b.serialCache.Len() * 256,
+		},
+	}, nil
+}
+
 func (b *backend) pathCRLWrite(
 	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 	name := strings.ToLower(d.Get("name").(string))
@@ -203,6 +663,18 @@ func (b *backend) pathCRLWrite(
 		return logical.ErrorResponse(`"name" parameter cannot be empty`), nil
 	}
 	crl := d.Get("crl").(string)
+	url := d.Get("url").(string)
+	if crl == "" && url == "" {
+		return logical.ErrorResponse(`one of "crl" or "url" must be set`), nil
+	}
+
+	if crl == "" {
+		fetched, err := fetchCRLFromURL(url)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("failed to fetch CRL from %s: %v", url, err)), nil
+		}
+		crl = fetched
+	}
 
 	certList, err := x509.ParseCRL([]byte(crl))
 	if err != nil {
@@ -212,36 +684,351 @@ func (b *backend) pathCRLWrite(
 		return logical.ErrorResponse("parsed CRL is nil"), nil
 	}
 
-	crlUpdateMutex.Lock()
-	defer crlUpdateMutex.Unlock()
+	crlInfo := buildCRLInfo(certList)
+	crlInfo.URL = url
+
+	lock := b.crlWriteLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := b.storeCRL(req.Storage, name, crlInfo); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// pathCRLRefreshWrite re-fetches a URL-backed CRL on demand, independent of
+// the background refresh ticker.
+func (b *backend) pathCRLRefreshWrite(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := strings.ToLower(d.Get("name").(string))
+	if name == "" {
+		return logical.ErrorResponse(`"name" parameter cannot be empty`), nil
+	}
+
+	existing, ok := b.getCRLMeta(name)
+	if !ok {
+		return logical.ErrorResponse(fmt.Sprintf("no such CRL %s", name)), nil
+	}
+	if existing.URL == "" {
+		return logical.ErrorResponse(fmt.Sprintf("CRL %s was not configured with a url", name)), nil
+	}
+
+	if err := b.refreshCRLFromURL(req.Storage, name); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return nil, nil
+}
+
+// crlFetchTimeout bounds how long a single CRL distribution point is given
+// to respond. fetchCRLFromURL is called both synchronously from the
+// background refresh goroutine and inline from pathCRLWrite, so a hung
+// distribution point without this would block CRL refreshes (and writes)
+// indefinitely.
+const crlFetchTimeout = 30 * time.Second
+
+var crlFetchClient = &http.Client{Timeout: crlFetchTimeout}
+
+// fetchCRLFromURL retrieves the raw CRL bytes (DER or PEM) from a CRL
+// distribution point.
+func fetchCRLFromURL(url string) (string, error) {
+	resp, err := crlFetchClient.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d fetching CRL", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// refreshCRLFromURL re-fetches and reparses a single URL-backed CRL,
+// updating both the in-memory cache and storage. It records the outcome
+// (including fetch errors) on CRLInfo rather than returning early, so
+// LastFetch/FetchError stay meaningful for the read endpoint. The whole
+// fetch-and-store sequence runs under this CRL's write lock (see
+// crlWriteLock) so it can't race a concurrent pathCRLWrite or refresh of
+// the same name.
+func (b *backend) refreshCRLFromURL(storage logical.Storage, name string) error {
+	lock := b.crlWriteLock(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	meta, ok := b.getCRLMeta(name)
+	if !ok || meta.URL == "" {
+		return nil
+	}
+
+	raw, fetchErr := fetchCRLFromURL(meta.URL)
+	var certList *pkix.CertificateList
+	if fetchErr == nil {
+		var parseErr error
+		certList, parseErr = x509.ParseCRL([]byte(raw))
+		if parseErr != nil {
+			fetchErr = fmt.Errorf("failed to parse CRL fetched from %s: %v", meta.URL, parseErr)
+		}
+	}
+	if fetchErr != nil {
+		meta.LastFetch = time.Now()
+		meta.FetchError = fetchErr.Error()
+		meta.NextRefresh = meta.LastFetch.Add(minCRLRefreshInterval)
+		b.setCRLMeta(name, meta)
+
+		entry, err := storage.Get("crls/" + name)
+		if err == nil && entry != nil {
+			var crlInfo CRLInfo
+			if entry.DecodeJSON(&crlInfo) == nil {
+				crlInfo.LastFetch = meta.LastFetch
+				crlInfo.FetchError = meta.FetchError
+				crlInfo.NextRefresh = meta.NextRefresh
+				if newEntry, err := logical.StorageEntryJSON("crls/"+name, crlInfo); err == nil {
+					storage.Put(newEntry)
+				}
+			}
+		}
+
+		return fetchErr
+	}
+
+	newInfo := buildCRLInfo(certList)
+	newInfo.URL = meta.URL
 
+	return b.storeCRL(storage, name, newInfo)
+}
+
+// buildCRLInfo parses a CertificateList into a CRLInfo, stamping the
+// fetch bookkeeping fields used by the background refresher and the
+// per-serial revocation metadata (reason, time, issuer) used during login.
+func buildCRLInfo(certList *pkix.CertificateList) CRLInfo {
 	crlInfo := CRLInfo{
 		Serials: map[string]RevokedSerialInfo{},
 	}
+
+	var issuer pkix.Name
+	issuer.FillFromRDNSequence(&certList.TBSCertList.Issuer)
+	issuerNameHash, _ := hashIssuerName(issuer)
+	crlInfo.IssuerNameHash = issuerNameHash
+
 	for _, revokedCert := range certList.TBSCertList.RevokedCertificates {
-		crlInfo.Serials[revokedCert.SerialNumber.String()] = RevokedSerialInfo{}
+		crlInfo.Serials[revokedCert.SerialNumber.String()] = RevokedSerialInfo{
+			RevocationTime: revokedCert.RevocationTime,
+			ReasonCode:     reasonCodeFromExtensions(revokedCert.Extensions),
+			IssuerNameHash: issuerNameHash,
+		}
+	}
+
+	crlInfo.LastFetch = time.Now()
+	crlInfo.NextRefresh = crlInfo.LastFetch.Add(nextRefreshInterval(certList.TBSCertList.NextUpdate))
+
+	return crlInfo
+}
+
+// nextRefreshInterval derives a refresh interval from a CRL's NextUpdate
+// field, clamped to [minCRLRefreshInterval, maxCRLRefreshInterval] so a
+// missing or outlandish NextUpdate can't starve or flood the distribution
+// point.
+func nextRefreshInterval(nextUpdate time.Time) time.Duration {
+	interval := nextUpdate.Sub(time.Now())
+	switch {
+	case interval < minCRLRefreshInterval:
+		return minCRLRefreshInterval
+	case interval > maxCRLRefreshInterval:
+		return maxCRLRefreshInterval
+	default:
+		return interval
+	}
+}
+
+// storeCRL persists a CRL's serials as independent entries under
+// crlSerialsPrefix(name), and its bookkeeping fields (URL, fetch times,
+// IssuerNameHash) as a separate, small metadata entry at "crls/<name>".
+// This is what lets lookupSerialInCRL serve a cold lookup against a huge
+// CRL without deserializing every other serial on it. A write always
+// replaces the CRL's entire set of serials, so any previously stored
+// serials not present in crlInfo.Serials are deleted first -- callers that
+// read crlInfo from existing state (refreshCRLFromURL, appendLocalRevocation)
+// must hold crlWriteLock(name) across the whole read-modify-write, or a
+// concurrent writer can have its serials wiped by this delete.
+func (b *backend) storeCRL(storage logical.Storage, name string, crlInfo CRLInfo) error {
+	if err := deleteCRLSerials(storage, name); err != nil {
+		return err
+	}
+
+	for serialHex, info := range crlInfo.Serials {
+		entry, err := logical.StorageEntryJSON(crlSerialStorageKey(name, serialHex), info)
+		if err != nil {
+			return err
+		}
+		if err := storage.Put(entry); err != nil {
+			return err
+		}
 	}
 
-	entry, err := logical.StorageEntryJSON("crls/"+name, crlInfo)
+	meta := crlInfo
+	meta.Serials = nil
+	entry, err := logical.StorageEntryJSON("crls/"+name, meta)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if err = req.Storage.Put(entry); err != nil {
-		return nil, err
+	if err := storage.Put(entry); err != nil {
+		return err
 	}
 
-	crls[name] = crlInfo
+	crlMeta := metaFromCRLInfo(crlInfo)
+	crlMeta.SerialCount = len(crlInfo.Serials)
+	b.setCRLMeta(name, crlMeta)
+	b.invalidateSerialCache(name)
 
-	return nil, nil
+	return nil
+}
+
+// deleteCRLSerials removes every per-serial storage entry recorded for a
+// CRL, without touching its metadata entry.
+func deleteCRLSerials(storage logical.Storage, name string) error {
+	keys, err := storage.List(crlSerialsPrefix(name))
+	if err != nil {
+		return err
+	}
+	for _, serialHex := range keys {
+		if err := storage.Delete(crlSerialStorageKey(name, serialHex)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadFullCRLInfo reconstructs a CRL's complete CRLInfo, including its
+// full Serials map, by combining the metadata entry with every per-serial
+// entry under crlSerialsPrefix(name). Unlike lookupSerialInCRL, this reads
+// the entire serial set and is only meant for the rarer "read the whole
+// CRL" and "append one more serial" paths, not the per-login lookup path.
+func loadFullCRLInfo(storage logical.Storage, name string) (CRLInfo, error) {
+	entry, err := storage.Get("crls/" + name)
+	if err != nil {
+		return CRLInfo{}, err
+	}
+	if entry == nil {
+		return CRLInfo{}, fmt.Errorf("no such CRL %s", name)
+	}
+
+	var crlInfo CRLInfo
+	if err := entry.DecodeJSON(&crlInfo); err != nil {
+		return CRLInfo{}, err
+	}
+
+	serialHexes, err := storage.List(crlSerialsPrefix(name))
+	if err != nil {
+		return CRLInfo{}, err
+	}
+
+	crlInfo.Serials = make(map[string]RevokedSerialInfo, len(serialHexes))
+	for _, serialHex := range serialHexes {
+		serialEntry, err := storage.Get(crlSerialStorageKey(name, serialHex))
+		if err != nil {
+			return CRLInfo{}, err
+		}
+		if serialEntry == nil {
+			continue
+		}
+		var info RevokedSerialInfo
+		if err := serialEntry.DecodeJSON(&info); err != nil {
+			return CRLInfo{}, err
+		}
+		crlInfo.Serials[serialHex] = info
+	}
+
+	return crlInfo, nil
+}
+
+// startCRLRefresher launches the single background goroutine responsible for
+// keeping this backend instance's URL-backed CRLs up to date. It is
+// intended to be called once from Factory, against that same call's
+// storage handle, and returns a channel that can be closed to stop it --
+// see Factory's use of Backend.Clean.
+func (b *backend) startCRLRefresher(storage logical.Storage) chan struct{} {
+	stopCh := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(minCRLRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				b.refreshDueCRLs(storage)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	return stopCh
+}
+
+func (b *backend) refreshDueCRLs(storage logical.Storage) {
+	now := time.Now()
+	for _, name := range b.crlNames() {
+		meta, ok := b.getCRLMeta(name)
+		if !ok || meta.URL == "" || meta.NextRefresh.After(now) {
+			continue
+		}
+		b.refreshCRLFromURL(storage, name)
+	}
 }
 
 type CRLInfo struct {
 	Serials map[string]RevokedSerialInfo `json:"serials" structs:"serials" mapstructure:"serials"`
+
+	URL            string    `json:"url,omitempty" structs:"url" mapstructure:"url"`
+	LastFetch      time.Time `json:"last_fetch,omitempty" structs:"last_fetch" mapstructure:"last_fetch"`
+	NextRefresh    time.Time `json:"next_refresh,omitempty" structs:"next_refresh" mapstructure:"next_refresh"`
+	FetchError     string    `json:"fetch_error,omitempty" structs:"fetch_error" mapstructure:"fetch_error"`
+	IssuerNameHash string    `json:"issuer_name_hash,omitempty" structs:"issuer_name_hash" mapstructure:"issuer_name_hash"`
 }
 
 type RevokedSerialInfo struct {
+	RevocationTime time.Time `json:"revocation_time,omitempty" structs:"revocation_time" mapstructure:"revocation_time"`
+	ReasonCode     int       `json:"reason_code" structs:"reason_code" mapstructure:"reason_code"`
+
+	// IssuerNameHash is the hash of the issuer that revoked this specific
+	// serial. It is redundant with CRLInfo.IssuerNameHash for a normal,
+	// single-issuer CRL, but is the only record of issuer for a multi-issuer
+	// CRL like _local (see appendLocalRevocation), whose CRLInfo.IssuerNameHash
+	// is left blank. findSerialInCRLs falls back to this field when a CRL's
+	// own IssuerNameHash is empty.
+	IssuerNameHash string `json:"issuer_name_hash,omitempty" structs:"issuer_name_hash" mapstructure:"issuer_name_hash"`
 }
 
+const pathCRLRefreshHelpSyn = `
+Force an immediate re-fetch of a URL-backed CRL.
+`
+
+const pathCRLRefreshHelpDesc = `
+When a CRL was configured with a "url", this endpoint fetches it immediately
+rather than waiting for the next scheduled refresh. It has no effect on CRLs
+that were uploaded inline via the "crl" parameter.
+`
+
+const pathCRLStatsHelpSyn = `
+Report cache statistics for the loaded CRLs.
+`
+
+const pathCRLStatsHelpDesc = `
+Returns the number of loaded CRLs, the total number of revoked serials
+across them, and hit-rate/size statistics for the in-memory serial lookup
+cache. Useful for sizing the cache when operating with very large CRLs.
+`
+
 const pathCRLsHelpSyn = `
 Manage Certificate Revocation Lists checked during authentication.
 `
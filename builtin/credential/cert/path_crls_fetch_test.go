@@ -0,0 +1,49 @@
+package cert
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// TestFetchCRLFromURL_Timeout verifies that fetchCRLFromURL gives up on an
+// unresponsive distribution point instead of blocking forever, since it's
+// called synchronously from both the background refresher and pathCRLWrite.
+func TestFetchCRLFromURL_Timeout(t *testing.T) {
+	orig := crlFetchClient.Timeout
+	crlFetchClient.Timeout = 200 * time.Millisecond
+	defer func() { crlFetchClient.Timeout = orig }()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer listener.Close()
+
+	// Accept connections but never write a response, simulating a hung
+	// distribution point.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := fetchCRLFromURL("http://" + listener.Addr().String() + "/crl")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("expected a timeout error fetching from an unresponsive server")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("fetchCRLFromURL did not respect the client timeout")
+	}
+}
@@ -0,0 +1,67 @@
+package cert
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// TestStoreCRL_PerSerialStorage verifies that storeCRL persists each serial
+// as its own storage entry (so a cold lookupSerialInCRL only ever reads the
+// one serial being checked) rather than a single blob containing every
+// serial on the CRL.
+func TestStoreCRL_PerSerialStorage(t *testing.T) {
+	storage := &logical.InmemStorage{}
+	b := Backend()
+
+	crlInfo := CRLInfo{
+		IssuerNameHash: "deadbeef",
+		Serials: map[string]RevokedSerialInfo{
+			"1": {ReasonCode: 1},
+			"2": {ReasonCode: 2},
+		},
+	}
+	if err := b.storeCRL(storage, "myca", crlInfo); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The metadata entry itself carries no serials.
+	entry, err := storage.Get("crls/myca")
+	if err != nil || entry == nil {
+		t.Fatalf("expected a metadata entry, got entry=%v err=%v", entry, err)
+	}
+	var meta CRLInfo
+	if err := entry.DecodeJSON(&meta); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(meta.Serials) != 0 {
+		t.Fatalf("expected the metadata entry to carry no serials, got %#v", meta.Serials)
+	}
+	if meta.IssuerNameHash != "deadbeef" {
+		t.Fatalf("expected metadata to retain IssuerNameHash, got %q", meta.IssuerNameHash)
+	}
+
+	// Each serial is independently readable.
+	for _, serialHex := range []string{"1", "2"} {
+		serialEntry, err := storage.Get(crlSerialStorageKey("myca", serialHex))
+		if err != nil || serialEntry == nil {
+			t.Fatalf("expected a per-serial entry for %s, got entry=%v err=%v", serialHex, serialEntry, err)
+		}
+	}
+
+	// A second write with a smaller serial set removes the stale entry.
+	if err := b.storeCRL(storage, "myca", CRLInfo{Serials: map[string]RevokedSerialInfo{"1": {}}}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if gone, err := storage.Get(crlSerialStorageKey("myca", "2")); err != nil || gone != nil {
+		t.Fatalf("expected serial 2 to be removed by the replacing write, got entry=%v err=%v", gone, err)
+	}
+
+	full, err := loadFullCRLInfo(storage, "myca")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(full.Serials) != 1 {
+		t.Fatalf("expected loadFullCRLInfo to reconstruct 1 serial, got %#v", full.Serials)
+	}
+}
@@ -0,0 +1,48 @@
+package cert
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// TestFindSerialInCRLs_MultiIssuerSerialCollision verifies that two
+// different CAs revoked through the same CRL (as _local is, via
+// appendLocalRevocation) can't shadow each other just because they
+// happen to reuse a serial number.
+func TestFindSerialInCRLs_MultiIssuerSerialCollision(t *testing.T) {
+	storage := &logical.InmemStorage{}
+	b := Backend()
+
+	crlInfo := CRLInfo{
+		Serials: map[string]RevokedSerialInfo{
+			"1": {IssuerNameHash: "aaaaaaaa"},
+			"2": {IssuerNameHash: "bbbbbbbb"},
+		},
+	}
+	if err := b.storeCRL(storage, localCRLName, crlInfo); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	serial := big.NewInt(1)
+
+	found := b.findSerialInCRLs(storage, serial, "aaaaaaaa")
+	if _, ok := found[localCRLName]; !ok {
+		t.Fatalf("expected serial 1 to match issuer aaaaaaaa, got %#v", found)
+	}
+
+	found = b.findSerialInCRLs(storage, serial, "bbbbbbbb")
+	if _, ok := found[localCRLName]; ok {
+		t.Fatalf("serial 1 belongs to issuer aaaaaaaa, should not match bbbbbbbb: %#v", found)
+	}
+}
+
+func TestRevocationStatus_CertificateHold(t *testing.T) {
+	if status := revocationStatus(RevokedSerialInfo{ReasonCode: reasonCodeCertificateHold}); status != "hold" {
+		t.Fatalf("expected hold, got %s", status)
+	}
+	if status := revocationStatus(RevokedSerialInfo{ReasonCode: 1}); status != "revoked" {
+		t.Fatalf("expected revoked, got %s", status)
+	}
+}
@@ -0,0 +1,285 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+	"golang.org/x/crypto/ocsp"
+)
+
+func pathOCSP(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "ocsp/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "The name of the trusted certificate this config applies to",
+			},
+
+			"responder_url": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `The URL of the OCSP responder to query for this
+certificate. If unset, the responder URL is taken from the Authority
+Information Access extension of the presented chain.`,
+			},
+
+			"fail_open": &framework.FieldSchema{
+				Type:    framework.TypeBool,
+				Default: false,
+				Description: `If true, a login proceeds when the OCSP
+responder cannot be reached or returns an error (soft-fail). If false
+(the default), an unreachable responder fails the login (hard-fail).`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.DeleteOperation: b.pathOCSPDelete,
+			logical.ReadOperation:   b.pathOCSPRead,
+			logical.WriteOperation:  b.pathOCSPWrite,
+		},
+
+		HelpSynopsis:    pathOCSPHelpSyn,
+		HelpDescription: pathOCSPHelpDesc,
+	}
+}
+
+// OCSPConfig holds the per-trusted-cert OCSP responder configuration.
+type OCSPConfig struct {
+	ResponderURL string `json:"responder_url" structs:"responder_url" mapstructure:"responder_url"`
+	FailOpen     bool   `json:"fail_open" structs:"fail_open" mapstructure:"fail_open"`
+}
+
+func (b *backend) pathOCSPDelete(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := strings.ToLower(d.Get("name").(string))
+	if name == "" {
+		return logical.ErrorResponse(`"name" parameter cannot be empty`), nil
+	}
+
+	if err := req.Storage.Delete("ocsp/" + name); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathOCSPRead(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := strings.ToLower(d.Get("name").(string))
+	if name == "" {
+		return logical.ErrorResponse(`"name" parameter cannot be empty`), nil
+	}
+
+	config, err := getOCSPConfig(req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"responder_url": config.ResponderURL,
+			"fail_open":     config.FailOpen,
+		},
+	}, nil
+}
+
+func (b *backend) pathOCSPWrite(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := strings.ToLower(d.Get("name").(string))
+	if name == "" {
+		return logical.ErrorResponse(`"name" parameter cannot be empty`), nil
+	}
+
+	config := &OCSPConfig{
+		ResponderURL: d.Get("responder_url").(string),
+		FailOpen:     d.Get("fail_open").(bool),
+	}
+
+	entry, err := logical.StorageEntryJSON("ocsp/"+name, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func getOCSPConfig(storage logical.Storage, name string) (*OCSPConfig, error) {
+	entry, err := storage.Get("ocsp/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config OCSPConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// ocspCacheKey identifies a cached OCSP response by issuer and serial.
+type ocspCacheKey struct {
+	issuer string
+	serial string
+}
+
+type ocspCacheEntry struct {
+	revoked    bool
+	nextUpdate time.Time
+}
+
+// ocspCacheSize bounds the cache of OCSP responses. Without a bound, a
+// process that sees enough distinct (issuer, serial) pairs over its
+// lifetime would grow this map without end, since entries were previously
+// never evicted or purged after NextUpdate.
+const ocspCacheSize = 100000
+
+var ocspCache = mustNewOCSPCache()
+
+// ocspFetchTimeout bounds how long a single OCSP responder is given to
+// respond, mirroring the crlFetchTimeout fix for the CRL fetch path: a hung
+// or slow-loris responder in hard-fail mode (the default) would otherwise
+// block the login request indefinitely.
+const ocspFetchTimeout = 30 * time.Second
+
+var ocspFetchClient = &http.Client{Timeout: ocspFetchTimeout}
+
+func mustNewOCSPCache() *lru.Cache {
+	c, err := lru.New(ocspCacheSize)
+	if err != nil {
+		// Only returns an error for a non-positive size, which is a
+		// programmer error, not a runtime condition.
+		panic(err)
+	}
+	return c
+}
+
+// checkOCSP consults an OCSP responder for every non-root certificate in
+// chain, in addition to the CRL checks performed by findSerialInCRLs. It
+// returns an error if any certificate in the chain is reported Revoked, or
+// if a responder is unreachable while configured to hard-fail. certName is
+// the trusted cert entry the chain is being authenticated against, used to
+// look up OCSPConfig.
+func checkOCSP(storage logical.Storage, certName string, chain []*x509.Certificate) error {
+	config, err := getOCSPConfig(storage, certName)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		// No OCSP configuration for this trusted cert; nothing to check.
+		return nil
+	}
+
+	for i := 0; i < len(chain)-1; i++ {
+		cert := chain[i]
+		issuer := chain[i+1]
+
+		revoked, err := checkOCSPForCert(config, cert, issuer)
+		if err != nil {
+			if config.FailOpen {
+				continue
+			}
+			return fmt.Errorf("error checking OCSP status for serial %s: %v", cert.SerialNumber, err)
+		}
+		if revoked {
+			return fmt.Errorf("certificate with serial %s is revoked per OCSP responder", cert.SerialNumber)
+		}
+	}
+
+	return nil
+}
+
+func checkOCSPForCert(config *OCSPConfig, cert, issuer *x509.Certificate) (bool, error) {
+	key := ocspCacheKey{issuer: issuer.Subject.String(), serial: cert.SerialNumber.String()}
+
+	if cachedVal, ok := ocspCache.Get(key); ok {
+		cached := cachedVal.(ocspCacheEntry)
+		if time.Now().Before(cached.nextUpdate) {
+			return cached.revoked, nil
+		}
+		// Expired; don't serve stale data, and don't let it linger in the
+		// cache past its NextUpdate.
+		ocspCache.Remove(key)
+	}
+
+	responderURL := config.ResponderURL
+	if responderURL == "" {
+		if len(cert.OCSPServer) == 0 {
+			return false, fmt.Errorf("no OCSP responder configured and no AIA OCSP server on certificate")
+		}
+		responderURL = cert.OCSPServer[0]
+	}
+
+	reqBytes, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return false, fmt.Errorf("error building OCSP request: %v", err)
+	}
+
+	httpResp, err := ocspFetchClient.Post(responderURL, "application/ocsp-request", bytes.NewReader(reqBytes))
+	if err != nil {
+		return false, fmt.Errorf("error contacting OCSP responder: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading OCSP response: %v", err)
+	}
+
+	ocspResp, err := ocsp.ParseResponseForCert(respBytes, cert, issuer)
+	if err != nil {
+		return false, fmt.Errorf("error parsing OCSP response: %v", err)
+	}
+
+	// ocsp.Unknown (the responder doesn't recognize this cert) is not the
+	// same as "not revoked" -- treat it as a failure subject to the same
+	// fail_open/fail_closed policy as an unreachable responder, rather than
+	// silently letting the login through. It's not cached, since an unknown
+	// status carries no meaningful NextUpdate to bound a cached entry by.
+	if ocspResp.Status != ocsp.Good && ocspResp.Status != ocsp.Revoked {
+		return false, fmt.Errorf("OCSP responder returned unknown status for serial %s", cert.SerialNumber)
+	}
+
+	revoked := ocspResp.Status == ocsp.Revoked
+
+	ocspCache.Add(key, ocspCacheEntry{revoked: revoked, nextUpdate: ocspResp.NextUpdate})
+
+	return revoked, nil
+}
+
+const pathOCSPHelpSyn = `
+Manage OCSP responder settings consulted during authentication.
+`
+
+const pathOCSPHelpDesc = `
+This endpoint allows you to register an OCSP responder URL for a trusted
+certificate, along with whether a login should soft-fail (proceed) or
+hard-fail (be denied) when the responder cannot be reached. If no
+responder_url is set, the responder embedded in the certificate's
+Authority Information Access extension is used instead.
+
+When configured, logins consult the responder for every certificate in the
+presented chain except the last (which has no issuer in the chain to build
+the request against), in addition to any CRLs loaded via crls/<name>. A response
+of "revoked" denies the login regardless of the CRL result. A response of
+"unknown" (the responder doesn't recognize the certificate) is treated the
+same as an unreachable responder and is subject to fail_open/fail_closed,
+not treated as "good".
+`
@@ -0,0 +1,98 @@
+package cert
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// TestCheckOCSPForCert_UnknownStatusIsAFailure verifies that an OCSP
+// responder reporting "unknown" for a certificate is treated as a failure
+// (subject to fail_open/fail_closed), not silently passed through as "not
+// revoked".
+func TestCheckOCSPForCert_UnknownStatusIsAFailure(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	leafCert, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	respTemplate := ocsp.Response{
+		Status:       ocsp.Unknown,
+		SerialNumber: leafCert.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+	}
+	respDER, err := ocsp.CreateResponse(caCert, caCert, respTemplate, caKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(respDER)
+	}))
+	defer server.Close()
+
+	config := &OCSPConfig{ResponderURL: server.URL, FailOpen: false}
+
+	revoked, err := checkOCSPForCert(config, leafCert, caCert)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown OCSP status, got revoked=%v, err=nil", revoked)
+	}
+	if revoked {
+		t.Fatalf("an unknown OCSP status should never report revoked=true")
+	}
+}
+
+// TestOCSPCache_Bounded verifies the response cache is a fixed-size LRU
+// rather than an unbounded map.
+func TestOCSPCache_Bounded(t *testing.T) {
+	for i := 0; i < ocspCacheSize+10; i++ {
+		key := ocspCacheKey{issuer: "issuer", serial: strconv.Itoa(i)}
+		ocspCache.Add(key, ocspCacheEntry{})
+	}
+	if ocspCache.Len() > ocspCacheSize {
+		t.Fatalf("expected the OCSP cache to stay bounded at %d entries, got %d", ocspCacheSize, ocspCache.Len())
+	}
+}
@@ -0,0 +1,431 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+// localCRLName is the name of the synthetic, backend-managed CRL that
+// "revoke" appends to. It is stored and cached exactly like any other named
+// CRL (see crls/<name>), but is built up incrementally instead of being
+// replaced wholesale by a single write.
+const localCRLName = "_local"
+
+func pathRevoke(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "revoke",
+		Fields: map[string]*framework.FieldSchema{
+			"certificate": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `A PEM-encoded certificate to revoke. The serial
+number and issuer are taken from the certificate itself.`,
+			},
+
+			"serial_number": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `The serial number of the certificate to revoke,
+used instead of "certificate". Accepts the same formats as the "serial"
+parameter on crls/<name>. Requires "issuer_name_hash" to also be set.`,
+			},
+
+			"issuer_name_hash": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `The issuer name hash (see crls/<name>'s
+issuer_name_hash) of the CA that issued the certificate being revoked by
+serial number. Ignored when "certificate" is set, since the hash is
+computed from the certificate.`,
+			},
+
+			"jws": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `An optional compact-serialization JWS, signed
+by the private key corresponding to "certificate", with a payload of
+{"serial":"<serial number>"}. Mirrors the proof-of-possession used by the
+ACME revocation flow. Requires "certificate".`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.WriteOperation: b.pathRevokeWrite,
+		},
+
+		HelpSynopsis:    pathRevokeHelpSyn,
+		HelpDescription: pathRevokeHelpDesc,
+	}
+}
+
+func pathLocalCRLConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/local_crl",
+		Fields: map[string]*framework.FieldSchema{
+			"certificate": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "PEM-encoded certificate used to sign the _local CRL.",
+			},
+
+			"private_key": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "PEM-encoded private key (PKCS1 RSA, SEC1 EC, or PKCS8) matching \"certificate\".",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.DeleteOperation: b.pathLocalCRLConfigDelete,
+			logical.ReadOperation:   b.pathLocalCRLConfigRead,
+			logical.WriteOperation:  b.pathLocalCRLConfigWrite,
+		},
+
+		HelpSynopsis:    pathLocalCRLConfigHelpSyn,
+		HelpDescription: pathLocalCRLConfigHelpDesc,
+	}
+}
+
+// localCRLSigningConfig holds the operator-configured key pair used to
+// self-sign the _local CRL on read.
+type localCRLSigningConfig struct {
+	Certificate string `json:"certificate" structs:"certificate" mapstructure:"certificate"`
+	PrivateKey  string `json:"private_key" structs:"private_key" mapstructure:"private_key"`
+}
+
+func (b *backend) pathLocalCRLConfigWrite(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config := &localCRLSigningConfig{
+		Certificate: d.Get("certificate").(string),
+		PrivateKey:  d.Get("private_key").(string),
+	}
+	if config.Certificate == "" || config.PrivateKey == "" {
+		return logical.ErrorResponse(`both "certificate" and "private_key" must be set`), nil
+	}
+
+	entry, err := logical.StorageEntryJSON("config/local_crl", config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathLocalCRLConfigRead(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	config, err := getLocalCRLSigningConfig(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, nil
+	}
+
+	// The private key is never returned once stored.
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"certificate": config.Certificate,
+		},
+	}, nil
+}
+
+func (b *backend) pathLocalCRLConfigDelete(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if err := req.Storage.Delete("config/local_crl"); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func getLocalCRLSigningConfig(storage logical.Storage) (*localCRLSigningConfig, error) {
+	entry, err := storage.Get("config/local_crl")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config localCRLSigningConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func (b *backend) pathRevokeWrite(
+	req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	certPEM := d.Get("certificate").(string)
+	serialStr := d.Get("serial_number").(string)
+	issuerNameHash := d.Get("issuer_name_hash").(string)
+	jws := d.Get("jws").(string)
+
+	var serial *big.Int
+	var cert *x509.Certificate
+
+	switch {
+	case certPEM != "":
+		block, _ := pem.Decode([]byte(certPEM))
+		if block == nil {
+			return logical.ErrorResponse("could not decode PEM certificate"), nil
+		}
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("error parsing certificate: %v", err)), nil
+		}
+		cert = parsed
+		serial = parsed.SerialNumber
+		if hash, err := hashIssuerName(parsed.Issuer); err == nil {
+			issuerNameHash = hash
+		}
+
+	case serialStr != "":
+		parsedSerial, err := parseSerialString(serialStr)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		if issuerNameHash == "" {
+			return logical.ErrorResponse(`"issuer_name_hash" is required when revoking by "serial_number"`), nil
+		}
+		serial = parsedSerial
+
+	default:
+		return logical.ErrorResponse(`one of "certificate" or "serial_number" must be set`), nil
+	}
+
+	if jws != "" {
+		if cert == nil {
+			return logical.ErrorResponse(`"jws" proof of possession requires "certificate"`), nil
+		}
+		if err := verifyRevocationJWS(cert, jws, serial); err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("JWS proof of possession failed: %v", err)), nil
+		}
+	}
+
+	if err := b.appendLocalRevocation(req.Storage, serial, issuerNameHash); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// appendLocalRevocation adds serial to the synthetic _local CRL without
+// disturbing any other serials already on it. Unlike an uploaded CRL, the
+// _local CRL can span multiple issuers, so (unlike crls/<name>) its
+// CRLInfo.IssuerNameHash is left blank; each serial instead carries its own
+// RevokedSerialInfo.IssuerNameHash, which findSerialInCRLs falls back to so
+// that a serial collision between two different CAs both revoked through
+// this endpoint still can't shadow each other.
+func (b *backend) appendLocalRevocation(storage logical.Storage, serial *big.Int, issuerNameHash string) error {
+	// storeCRL's write deletes every existing per-serial entry for
+	// localCRLName before rewriting the ones known to crlInfo.Serials, so
+	// this whole load-modify-store sequence has to run under the CRL's
+	// write lock: two concurrent revokes racing here would otherwise have
+	// the second one's store wipe the serial the first one just added.
+	lock := b.crlWriteLock(localCRLName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	crlInfo, err := loadFullCRLInfo(storage, localCRLName)
+	if err != nil {
+		// No _local CRL yet; this is the first revocation recorded against it.
+		crlInfo = CRLInfo{}
+	}
+	if crlInfo.Serials == nil {
+		crlInfo.Serials = map[string]RevokedSerialInfo{}
+	}
+
+	crlInfo.Serials[serial.String()] = RevokedSerialInfo{
+		RevocationTime: time.Now(),
+		IssuerNameHash: issuerNameHash,
+	}
+
+	return b.storeCRL(storage, localCRLName, crlInfo)
+}
+
+// parseLocalCRLSigningKey accepts the PKCS1 RSA, SEC1 EC, or PKCS8 (RSA or
+// EC) encodings that PEM-encoded private keys are commonly found in, since
+// an operator configuring config/local_crl may reasonably have any of them
+// on hand.
+func parseLocalCRLSigningKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("PKCS8 key type %T cannot sign", key)
+		}
+		return signer, nil
+	}
+	return nil, fmt.Errorf("error parsing signing key: unsupported or malformed key (expected PKCS1 RSA, SEC1 EC, or PKCS8)")
+}
+
+// buildLocalCRLDER renders the current _local CRL as a DER-encoded,
+// self-signed pkix.CertificateList, using the key configured at
+// config/local_crl.
+func buildLocalCRLDER(storage logical.Storage, crlInfo CRLInfo) ([]byte, error) {
+	config, err := getLocalCRLSigningConfig(storage)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, fmt.Errorf("no signing key configured at config/local_crl")
+	}
+
+	certBlock, _ := pem.Decode([]byte(config.Certificate))
+	if certBlock == nil {
+		return nil, fmt.Errorf("could not decode configured signing certificate")
+	}
+	signingCert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signing certificate: %v", err)
+	}
+
+	keyBlock, _ := pem.Decode([]byte(config.PrivateKey))
+	if keyBlock == nil {
+		return nil, fmt.Errorf("could not decode configured signing key")
+	}
+	signingKey, err := parseLocalCRLSigningKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := make([]pkix.RevokedCertificate, 0, len(crlInfo.Serials))
+	for serialStr, info := range crlInfo.Serials {
+		serial, ok := new(big.Int).SetString(serialStr, 10)
+		if !ok {
+			continue
+		}
+		revoked = append(revoked, pkix.RevokedCertificate{
+			SerialNumber:   serial,
+			RevocationTime: info.RevocationTime,
+		})
+	}
+
+	der, err := signingCert.CreateCRL(rand.Reader, signingKey, revoked, time.Now(), time.Now().Add(24*time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("error signing local CRL: %v", err)
+	}
+
+	return der, nil
+}
+
+// verifyRevocationJWS checks a compact-serialization JWS (header.payload.signature,
+// base64url without padding) against cert's public key, requiring the
+// payload to name the serial being revoked. This establishes possession of
+// the certificate's private key, following the shape of ACME's revocation
+// proof without pulling in a full JOSE library.
+func verifyRevocationJWS(cert *x509.Certificate, jws string, serial *big.Int) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWS: expected 3 dot-separated parts")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed JWS header: %v", err)
+	}
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("malformed JWS payload: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed JWS signature: %v", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return fmt.Errorf("malformed JWS header: %v", err)
+	}
+
+	var payload struct {
+		Serial string `json:"serial"`
+	}
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		return fmt.Errorf("malformed JWS payload: %v", err)
+	}
+	if payload.Serial != serial.String() {
+		return fmt.Errorf("JWS payload serial does not match the certificate being revoked")
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	digest := sha256.Sum256(signingInput)
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if header.Alg != "RS256" {
+			return fmt.Errorf("unsupported JWS algorithm %q for RSA certificate", header.Alg)
+		}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature)
+	case *ecdsa.PublicKey:
+		if header.Alg != "ES256" {
+			return fmt.Errorf("unsupported JWS algorithm %q for ECDSA certificate", header.Alg)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("malformed ES256 signature")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported certificate public key type")
+	}
+}
+
+const pathRevokeHelpSyn = `
+Revoke a single certificate by appending it to the backend-managed _local CRL.
+`
+
+const pathRevokeHelpDesc = `
+This endpoint lets an operator revoke one certificate -- by PEM certificate
+or by serial_number/issuer_name_hash pair -- without generating and
+uploading a full CRL out-of-band. The serial is appended to a synthetic CRL
+named "_local", which is consulted during login exactly like any CRL loaded
+via crls/<name>.
+
+An optional "jws" parameter, containing a compact-serialization JWS signed
+by the certificate's own private key over {"serial": "<serial number>"},
+follows the shape of the ACME revocation flow to prove the caller actually
+holds the key before the revocation is accepted.
+
+Reading crls/_local returns the usual JSON serial listing, plus a "crl_der"
+field containing a DER-encoded, self-signed pkix.CertificateList built from
+the key configured at config/local_crl. If no signing key is configured yet,
+or it can't be used to sign (see config/local_crl), the serial listing is
+still returned, with a "crl_der_error" field describing the problem in
+place of "crl_der".
+`
+
+const pathLocalCRLConfigHelpSyn = `
+Configure the signing key used for the _local CRL.
+`
+
+const pathLocalCRLConfigHelpDesc = `
+The _local CRL, built up by the "revoke" endpoint, is exposed on read as a
+DER-encoded, self-signed CRL. This endpoint configures the certificate and
+private key (PKCS1 RSA, SEC1 EC, or PKCS8) used to sign it. The private key
+is never returned by a read.
+`
@@ -0,0 +1,99 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// TestAppendLocalRevocation_Accumulates verifies that repeated calls add to
+// the _local CRL rather than each one clobbering the last, now that its
+// serials live in independent per-serial storage entries rather than a
+// single blob.
+func TestAppendLocalRevocation_Accumulates(t *testing.T) {
+	storage := &logical.InmemStorage{}
+	b := Backend()
+
+	if err := b.appendLocalRevocation(storage, big.NewInt(1), "aaaaaaaa"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := b.appendLocalRevocation(storage, big.NewInt(2), "bbbbbbbb"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	crlInfo, err := loadFullCRLInfo(storage, localCRLName)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(crlInfo.Serials) != 2 {
+		t.Fatalf("expected both revocations to accumulate, got %#v", crlInfo.Serials)
+	}
+	if crlInfo.Serials["1"].IssuerNameHash != "aaaaaaaa" || crlInfo.Serials["2"].IssuerNameHash != "bbbbbbbb" {
+		t.Fatalf("expected per-serial issuer hashes to be preserved, got %#v", crlInfo.Serials)
+	}
+}
+
+// TestBuildLocalCRLDER_ECKey verifies a SEC1-encoded ECDSA signing key can
+// sign the _local CRL, not just PKCS1 RSA.
+func TestBuildLocalCRLDER_ECKey(t *testing.T) {
+	storage := &logical.InmemStorage{}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "local crl signer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	config := &localCRLSigningConfig{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})),
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+	entry, err := logical.StorageEntryJSON("config/local_crl", config)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := storage.Put(entry); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := buildLocalCRLDER(storage, CRLInfo{Serials: map[string]RevokedSerialInfo{}}); err != nil {
+		t.Fatalf("expected an EC signing key to work, got: %v", err)
+	}
+}
+
+// TestBuildLocalCRLDER_NoConfigIsAnError verifies that a missing signing
+// key produces an error, which pathCRLRead degrades into a crl_der_error
+// field rather than failing the whole read.
+func TestBuildLocalCRLDER_NoConfigIsAnError(t *testing.T) {
+	storage := &logical.InmemStorage{}
+
+	if _, err := buildLocalCRLDER(storage, CRLInfo{Serials: map[string]RevokedSerialInfo{}}); err == nil {
+		t.Fatalf("expected an error with no signing key configured")
+	}
+}